@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// ExporterMode selects whether the exporter pulls cost data for the single
+// account it runs in, or fans out across an AWS Organization.
+type ExporterMode string
+
+const (
+	ModeSingleAccount ExporterMode = "single"
+	ModeOrganization  ExporterMode = "organization"
+
+	defaultAssumeRoleName = "CostExporterRole"
+)
+
+// Account is a discovered AWS Organizations member account, paired with a
+// Cost Explorer client scoped to that account via an assumed role.
+type Account struct {
+	ID     string
+	Name   string
+	client *costexplorer.Client
+}
+
+// AccountFilter restricts the set of accounts that will be queried when
+// running in organization mode. An empty allow list means "allow every
+// account except those in the deny list".
+type AccountFilter struct {
+	Allow map[string]struct{}
+	Deny  map[string]struct{}
+}
+
+func newAccountFilter(allowCSV, denyCSV string) *AccountFilter {
+	f := &AccountFilter{Allow: map[string]struct{}{}, Deny: map[string]struct{}{}}
+	for _, id := range splitCSV(allowCSV) {
+		f.Allow[id] = struct{}{}
+	}
+	for _, id := range splitCSV(denyCSV) {
+		f.Deny[id] = struct{}{}
+	}
+	return f
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Permits reports whether accountID should be queried.
+func (f *AccountFilter) Permits(accountID string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Allow) > 0 {
+		_, ok := f.Allow[accountID]
+		return ok
+	}
+	_, denied := f.Deny[accountID]
+	return !denied
+}
+
+// discoverAccounts lists the active accounts in the AWS Organization and
+// assumes roleName in each one (skipping accounts the filter rejects),
+// returning a Cost Explorer client scoped to every account that was
+// reachable.
+func discoverAccounts(ctx context.Context, baseCfg aws.Config, orgClient *organizations.Client, stsClient *sts.Client, roleName string, filter *AccountFilter) ([]Account, error) {
+	var accounts []Account
+
+	paginator := organizations.NewListAccountsPaginator(orgClient, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization accounts: %w", err)
+		}
+
+		for _, acct := range page.Accounts {
+			if acct.Status != orgTypes.AccountStatusActive {
+				continue
+			}
+
+			id := aws.ToString(acct.Id)
+			name := aws.ToString(acct.Name)
+
+			if !filter.Permits(id) {
+				log.Printf("Skipping account %s (%s): excluded by account filter", id, name)
+				continue
+			}
+
+			client, err := assumeRoleClient(ctx, baseCfg, stsClient, id, roleName)
+			if err != nil {
+				log.Printf("Failed to assume role %s in account %s (%s): %v", roleName, id, name, err)
+				continue
+			}
+
+			accounts = append(accounts, Account{ID: id, Name: name, client: client})
+		}
+	}
+
+	return accounts, nil
+}
+
+// assumeRoleClient assumes roleName in accountID and returns a Cost
+// Explorer client using the resulting temporary credentials.
+func assumeRoleClient(ctx context.Context, baseCfg aws.Config, stsClient *sts.Client, accountID, roleName string) (*costexplorer.Client, error) {
+	roleArn := fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, roleName)
+
+	out, err := stsClient.AssumeRole(ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleArn),
+		RoleSessionName: aws.String("aws-cost-exporter"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sts:AssumeRole %s: %w", roleArn, err)
+	}
+
+	accountCfg := baseCfg.Copy()
+	accountCfg.Credentials = aws.NewCredentialsCache(credentials.StaticCredentialsProvider{
+		Value: aws.Credentials{
+			AccessKeyID:     aws.ToString(out.Credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(out.Credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(out.Credentials.SessionToken),
+			Expires:         aws.ToTime(out.Credentials.Expiration),
+			CanExpire:       true,
+		},
+	})
+
+	return costexplorer.NewFromConfig(accountCfg), nil
+}
+
+// currentAccount resolves the account ID the exporter is running as, used
+// to label metrics in single-account mode. The returned Account's Name is
+// left empty.
+func currentAccount(ctx context.Context, stsClient *sts.Client) Account {
+	out, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		log.Printf("Failed to determine current AWS account identity: %v", err)
+		return Account{}
+	}
+	return Account{ID: aws.ToString(out.Account)}
+}
+
+func modeFromEnv() ExporterMode {
+	switch strings.ToLower(os.Getenv("EXPORTER_MODE")) {
+	case "organization", "org":
+		return ModeOrganization
+	default:
+		return ModeSingleAccount
+	}
+}
+
+func assumeRoleNameFromEnv() string {
+	if name := os.Getenv("ASSUME_ROLE_NAME"); name != "" {
+		return name
+	}
+	return defaultAssumeRoleName
+}
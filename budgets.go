@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+	budgetsTypes "github.com/aws/aws-sdk-go-v2/service/budgets/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newBudgetGauges builds the TTL-backed gauges behind the budget metrics
+// and registers them with Prometheus.
+func newBudgetGauges(ttl time.Duration) (limit, actual, forecasted, percentUsed *ttlGaugeVec) {
+	limit = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_budget_limit_usd",
+		Help: "Configured AWS Budgets limit in USD",
+	}, []string{"budget_name"}, ttl)
+
+	actual = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_budget_actual_usd",
+		Help: "Actual AWS spend against a budget in USD",
+	}, []string{"budget_name"}, ttl)
+
+	forecasted = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_budget_forecasted_usd",
+		Help: "Forecasted AWS spend against a budget in USD",
+	}, []string{"budget_name"}, ttl)
+
+	percentUsed = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_budget_percent_used",
+		Help: "Percentage of an AWS Budget's limit consumed by actual spend",
+	}, []string{"budget_name"}, ttl)
+
+	prometheus.MustRegister(limit.gauge, actual.gauge, forecasted.gauge, percentUsed.gauge)
+	return limit, actual, forecasted, percentUsed
+}
+
+// updateBudgetMetrics lists every AWS Budget for the caller's account and
+// exposes its limit, actual spend, forecasted spend, and percent-used as
+// gauges, mirroring the budget data available in the AWS Budgets console.
+func (e *CostExporter) updateBudgetMetrics(ctx context.Context) error {
+	accountID := currentAccount(ctx, e.stsClient).ID
+	if accountID == "" {
+		return fmt.Errorf("failed to resolve account ID for budgets lookup")
+	}
+
+	log.Printf("Fetching AWS Budgets for account %s", accountID)
+
+	metricsCount := 0
+
+	paginator := budgets.NewDescribeBudgetsPaginator(e.budgetsClient, &budgets.DescribeBudgetsInput{
+		AccountId: aws.String(accountID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Printf("Failed to describe AWS Budgets: %v", err)
+			return fmt.Errorf("failed to describe budgets: %w", err)
+		}
+
+		for _, budget := range page.Budgets {
+			name := aws.ToString(budget.BudgetName)
+
+			if limit, ok := budgetAmount(budget.BudgetLimit); ok {
+				e.budgetLimitGauge.Set([]string{name}, limit)
+			}
+
+			var hasActual bool
+			var actual float64
+			if budget.CalculatedSpend != nil {
+				actual, hasActual = budgetAmount(budget.CalculatedSpend.ActualSpend)
+				if hasActual {
+					e.budgetActualGauge.Set([]string{name}, actual)
+				}
+
+				if forecasted, ok := budgetAmount(budget.CalculatedSpend.ForecastedSpend); ok {
+					e.budgetForecastedGauge.Set([]string{name}, forecasted)
+				}
+			}
+
+			if limit, ok := budgetAmount(budget.BudgetLimit); ok && limit > 0 && hasActual {
+				e.budgetPercentUsedGauge.Set([]string{name}, actual/limit*100)
+			}
+
+			metricsCount++
+		}
+	}
+
+	log.Printf("Updated budget metrics for %d AWS Budgets", metricsCount)
+	return nil
+}
+
+func budgetAmount(spend *budgetsTypes.Spend) (float64, bool) {
+	if spend == nil || spend.Amount == nil {
+		return 0, false
+	}
+	amount, err := strconv.ParseFloat(*spend.Amount, 64)
+	if err != nil {
+		return 0, false
+	}
+	return amount, true
+}
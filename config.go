@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"gopkg.in/yaml.v3"
+)
+
+// maxGroupByDimensions mirrors the AWS Cost Explorer GetCostAndUsage
+// limit of two GroupBy dimensions per request.
+const maxGroupByDimensions = 2
+
+// CollectorConfig controls which dimensions cost data is grouped by and
+// which Cost Explorer filter expression is applied. It is loaded from a
+// YAML file so operators can retag their cost breakdown without a
+// rebuild. A dimension of the form "TAG:<key>" groups by a cost
+// allocation tag instead of a built-in Cost Explorer dimension.
+type CollectorConfig struct {
+	Dimensions []string      `yaml:"dimensions"`
+	Filter     *FilterConfig `yaml:"filter"`
+}
+
+// FilterConfig mirrors a small, composable subset of the Cost Explorer
+// Expression grammar: a dimension or tag match, optionally negated or
+// combined with further nested expressions via And/Or.
+type FilterConfig struct {
+	Dimension *ValueFilter   `yaml:"dimension"`
+	Tag       *ValueFilter   `yaml:"tag"`
+	Not       *FilterConfig  `yaml:"not"`
+	And       []FilterConfig `yaml:"and"`
+	Or        []FilterConfig `yaml:"or"`
+}
+
+// ValueFilter matches a dimension or tag key against a set of values.
+type ValueFilter struct {
+	Key    string   `yaml:"key"`
+	Values []string `yaml:"values"`
+}
+
+func defaultCollectorConfig() CollectorConfig {
+	return CollectorConfig{
+		Dimensions: []string{string(types.DimensionService), string(types.DimensionRegion)},
+	}
+}
+
+// loadCollectorConfig reads the YAML config at path, falling back to the
+// exporter's historical SERVICE+REGION grouping with no filter when the
+// file does not exist, so existing deployments keep working unconfigured.
+func loadCollectorConfig(path string) (CollectorConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.Printf("No collector config found at %s, using default SERVICE+REGION grouping", path)
+		return defaultCollectorConfig(), nil
+	}
+	if err != nil {
+		return CollectorConfig{}, fmt.Errorf("failed to read collector config %s: %w", path, err)
+	}
+
+	var cfg CollectorConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return CollectorConfig{}, fmt.Errorf("failed to parse collector config %s: %w", path, err)
+	}
+
+	if len(cfg.Dimensions) == 0 {
+		cfg.Dimensions = defaultCollectorConfig().Dimensions
+	}
+
+	if err := validateDimensions(cfg.Dimensions); err != nil {
+		return CollectorConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+// validateDimensions rejects configurations that would exceed the AWS
+// Cost Explorer GroupBy limit of two dimensions per request.
+func validateDimensions(dimensions []string) error {
+	if len(dimensions) > maxGroupByDimensions {
+		return fmt.Errorf("cost explorer GroupBy supports at most %d dimensions, got %d: %v", maxGroupByDimensions, len(dimensions), dimensions)
+	}
+	return nil
+}
+
+// groupDefinitions converts the configured dimension names into Cost
+// Explorer GroupDefinitions.
+func groupDefinitions(dimensions []string) []types.GroupDefinition {
+	defs := make([]types.GroupDefinition, 0, len(dimensions))
+	for _, dim := range dimensions {
+		defs = append(defs, groupDefinition(dim))
+	}
+	return defs
+}
+
+func groupDefinition(dim string) types.GroupDefinition {
+	if key, ok := strings.CutPrefix(dim, "TAG:"); ok {
+		return types.GroupDefinition{
+			Type: types.GroupDefinitionTypeTag,
+			Key:  aws.String(key),
+		}
+	}
+	return types.GroupDefinition{
+		Type: types.GroupDefinitionTypeDimension,
+		Key:  aws.String(dim),
+	}
+}
+
+// dimensionLabelNames converts the configured dimension names into
+// Prometheus label names, e.g. "SERVICE" -> "service" and "TAG:Team" ->
+// "tag_team".
+func dimensionLabelNames(dimensions []string) []string {
+	names := make([]string, 0, len(dimensions))
+	for _, dim := range dimensions {
+		names = append(names, dimensionLabelName(dim))
+	}
+	return names
+}
+
+func dimensionLabelName(dim string) string {
+	if key, ok := strings.CutPrefix(dim, "TAG:"); ok {
+		return "tag_" + sanitizeLabel(key)
+	}
+	return strings.ToLower(dim)
+}
+
+func sanitizeLabel(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// expression converts the FilterConfig tree into a Cost Explorer
+// Expression for use as GetCostAndUsageInput.Filter. Returns nil if no
+// filter was configured.
+func (f *FilterConfig) expression() *types.Expression {
+	if f == nil {
+		return nil
+	}
+
+	switch {
+	case f.Dimension != nil:
+		return &types.Expression{
+			Dimensions: &types.DimensionValues{
+				Key:    types.Dimension(f.Dimension.Key),
+				Values: f.Dimension.Values,
+			},
+		}
+	case f.Tag != nil:
+		return &types.Expression{
+			Tags: &types.TagValues{
+				Key:    aws.String(f.Tag.Key),
+				Values: f.Tag.Values,
+			},
+		}
+	case f.Not != nil:
+		return &types.Expression{Not: f.Not.expression()}
+	case len(f.And) > 0:
+		return &types.Expression{And: subExpressions(f.And)}
+	case len(f.Or) > 0:
+		return &types.Expression{Or: subExpressions(f.Or)}
+	}
+
+	return nil
+}
+
+func subExpressions(filters []FilterConfig) []types.Expression {
+	exprs := make([]types.Expression, 0, len(filters))
+	for _, sub := range filters {
+		if e := sub.expression(); e != nil {
+			exprs = append(exprs, *e)
+		}
+	}
+	return exprs
+}
+
+func collectorConfigPathFromEnv() string {
+	if path := os.Getenv("COST_EXPORTER_CONFIG"); path != "" {
+		return path
+	}
+	return "config.yaml"
+}
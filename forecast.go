@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// forecastPredictionIntervalLevel is the confidence level AWS uses to
+// compute the upper/lower bounds returned alongside each forecast.
+const forecastPredictionIntervalLevel = 80
+
+// newForecastGauges builds the TTL-backed gauges behind the forecast
+// metrics and registers them with Prometheus.
+func newForecastGauges(ttl time.Duration) (cost, lower, upper *ttlGaugeVec) {
+	cost = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_forecast_cost_usd",
+		Help: "Forecasted AWS cost in USD for the upcoming period, as a point estimate",
+	}, []string{"period"}, ttl)
+
+	lower = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_forecast_cost_usd_lower_bound",
+		Help: "Lower bound of the AWS cost forecast prediction interval, in USD",
+	}, []string{"period"}, ttl)
+
+	upper = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_forecast_cost_usd_upper_bound",
+		Help: "Upper bound of the AWS cost forecast prediction interval, in USD",
+	}, []string{"period"}, ttl)
+
+	prometheus.MustRegister(cost.gauge, lower.gauge, upper.gauge)
+	return cost, lower, upper
+}
+
+// updateForecastMetrics fetches a daily and a monthly cost forecast from
+// Cost Explorer and exposes them as aws_forecast_cost_usd, labeled by
+// period ("DAILY"/"MONTHLY"). GetCostForecast has no GroupBy, so the
+// forecast is account-wide rather than broken down by service.
+func (e *CostExporter) updateForecastMetrics(ctx context.Context) error {
+	if err := e.updateForecastForGranularity(ctx, "DAILY", types.GranularityDaily, 1, 0); err != nil {
+		return err
+	}
+
+	e.spaceCostExplorerCall()
+	if err := e.updateForecastForGranularity(ctx, "MONTHLY", types.GranularityMonthly, 0, 1); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *CostExporter) updateForecastForGranularity(ctx context.Context, period string, granularity types.Granularity, addDays, addMonths int) error {
+	now := time.Now()
+	start := now.Format("2006-01-02")
+
+	var end time.Time
+	if addMonths > 0 {
+		firstOfNextMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, addMonths+1, 0)
+		end = firstOfNextMonth
+	} else {
+		end = now.AddDate(0, 0, addDays+1)
+	}
+
+	endStr := end.Format("2006-01-02")
+
+	log.Printf("Fetching %s cost forecast from %s to %s", period, start, endStr)
+
+	forecastInput := &costexplorer.GetCostForecastInput{
+		TimePeriod: &types.DateInterval{
+			Start: &start,
+			End:   &endStr,
+		},
+		Granularity:             granularity,
+		Metric:                  types.MetricUnblendedCost,
+		PredictionIntervalLevel: aws.Int32(forecastPredictionIntervalLevel),
+	}
+
+	result, err := callCostExplorer(ctx, e, "GetCostForecast", func() (*costexplorer.GetCostForecastOutput, error) {
+		return e.client.GetCostForecast(ctx, forecastInput)
+	})
+	if err != nil {
+		log.Printf("Failed to fetch %s cost forecast from AWS Cost Explorer: %v", period, err)
+		return fmt.Errorf("failed to get %s cost forecast: %w", period, err)
+	}
+
+	metricsCount := 0
+	if result.Total != nil && result.Total.Amount != nil {
+		if amount, err := strconv.ParseFloat(*result.Total.Amount, 64); err == nil {
+			e.forecastCostGauge.Set([]string{period}, amount)
+			metricsCount++
+		}
+	}
+
+	for _, forecastResult := range result.ForecastResultsByTime {
+		if forecastResult.PredictionIntervalLowerBound != nil {
+			if amount, err := strconv.ParseFloat(*forecastResult.PredictionIntervalLowerBound, 64); err == nil {
+				e.forecastCostLowerBoundGauge.Set([]string{period}, amount)
+			}
+		}
+		if forecastResult.PredictionIntervalUpperBound != nil {
+			if amount, err := strconv.ParseFloat(*forecastResult.PredictionIntervalUpperBound, 64); err == nil {
+				e.forecastCostUpperBoundGauge.Set([]string{period}, amount)
+			}
+		}
+	}
+
+	log.Printf("Updated %d %s forecast metrics for period %s to %s", metricsCount, period, start, endStr)
+	return nil
+}
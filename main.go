@@ -7,191 +7,299 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var (
-	awsCostGauge = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "aws_daily_cost_usd",
-			Help: "Daily AWS cost in USD",
-		},
-		[]string{"service", "region"},
-	)
-	
-	awsMonthlyCostGauge = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "aws_monthly_cost_usd",
-			Help: "Monthly AWS cost in USD",
-		},
-		[]string{"service", "region"},
-	)
-	
-	awsPreviousDayCostGauge = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "aws_previous_day_cost_usd",
-			Help: "Previous day AWS cost in USD (stable metric)",
-		},
-		[]string{"service", "region"},
-	)
-	
-	awsPreviousMonthCostGauge = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "aws_previous_month_cost_usd",
-			Help: "Previous month AWS cost in USD (stable metric)",
-		},
-		[]string{"service", "region"},
-	)
-)
+// costCollector is one of the exporter's time-windowed GetCostAndUsage
+// collectors: its Prometheus gauge, the granularity to query at, and the
+// time window to query. The dimensions grouped by and the filter applied
+// are shared across all collectors via CostExporter.collectorConfig.
+type costCollector struct {
+	name        string
+	gauge       *ttlGaugeVec
+	granularity types.Granularity
+	timeWindow  func(now time.Time) (start, end string)
+}
 
-type CostExporter struct {
-	client *costexplorer.Client
+// costMetricTypes are the Cost Explorer cost metrics requested in a
+// single GetCostAndUsage call, so that amortized/blended views of spend -
+// which Savings Plans and Reserved Instances make diverge from the
+// unblended, pay-as-you-go view - are all visible without extra calls.
+// UsageQuantity is a usage amount in the usage type's own unit, not USD.
+var costMetricTypes = []string{
+	"UnblendedCost",
+	"AmortizedCost",
+	"NetAmortizedCost",
+	"BlendedCost",
+	"UsageQuantity",
+}
+
+func newCostCollector(metricName, help string, granularity types.Granularity, labelNames []string, ttl time.Duration, timeWindow func(now time.Time) (start, end string)) costCollector {
+	return costCollector{
+		name: metricName,
+		gauge: newTTLGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName,
+				Help: help,
+			},
+			append(append([]string{}, labelNames...), "metric_type", "account_id", "account_name"),
+			ttl,
+		),
+		granularity: granularity,
+		timeWindow:  timeWindow,
+	}
 }
 
-func init() {
-	prometheus.MustRegister(awsCostGauge)
-	prometheus.MustRegister(awsMonthlyCostGauge)
-	prometheus.MustRegister(awsPreviousDayCostGauge)
-	prometheus.MustRegister(awsPreviousMonthCostGauge)
+func dailyWindow(now time.Time) (string, string) {
+	return now.AddDate(0, 0, -1).Format("2006-01-02"), now.Format("2006-01-02")
 }
 
-func NewCostExporter(client *costexplorer.Client) *CostExporter {
-	return &CostExporter{client: client}
+func monthlyWindow(now time.Time) (string, string) {
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return startOfMonth.Format("2006-01-02"), now.Format("2006-01-02")
 }
 
-func (e *CostExporter) updateDailyMetrics(ctx context.Context) error {
-	now := time.Now()
+func previousDayWindow(now time.Time) (string, string) {
+	twoDaysAgo := now.AddDate(0, 0, -2)
 	yesterday := now.AddDate(0, 0, -1)
-	
-	start := yesterday.Format("2006-01-02")
-	end := now.Format("2006-01-02")
+	return twoDaysAgo.Format("2006-01-02"), yesterday.Format("2006-01-02")
+}
 
-	log.Printf("Fetching daily cost data from %s to %s", start, end)
+func previousMonthWindow(now time.Time) (string, string) {
+	firstDayOfCurrentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	firstDayOfPreviousMonth := firstDayOfCurrentMonth.AddDate(0, -1, 0)
+	return firstDayOfPreviousMonth.Format("2006-01-02"), firstDayOfCurrentMonth.Format("2006-01-02")
+}
 
-	costInput := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &types.DateInterval{
-			Start: &start,
-			End:   &end,
-		},
-		Granularity: types.GranularityDaily,
-		Metrics:     []string{"UnblendedCost"},
-		GroupBy: []types.GroupDefinition{
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionService)}[0],
-			},
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionRegion)}[0],
-			},
-		},
-	}
+// CostExporter collects AWS cost metrics, either for the single account it
+// runs in or, in organization mode, for every member account of the AWS
+// Organization it is the management account of.
+type CostExporter struct {
+	client        *costexplorer.Client
+	orgClient     *organizations.Client
+	stsClient     *sts.Client
+	budgetsClient *budgets.Client
+
+	mode           ExporterMode
+	assumeRoleName string
+	accountFilter  *AccountFilter
+
+	collectorConfig CollectorConfig
+	filter          *types.Expression
+	collectors      []costCollector
+
+	accountCostGauge *ttlGaugeVec
+	metricsTTL       time.Duration
+
+	forecastCostGauge           *ttlGaugeVec
+	forecastCostLowerBoundGauge *ttlGaugeVec
+	forecastCostUpperBoundGauge *ttlGaugeVec
+
+	budgetLimitGauge       *ttlGaugeVec
+	budgetActualGauge      *ttlGaugeVec
+	budgetForecastedGauge  *ttlGaugeVec
+	budgetPercentUsedGauge *ttlGaugeVec
+
+	savingsPlans *commitmentGauges
+	reservations *commitmentGauges
+
+	rightsizingGauge       *ttlGaugeVec
+	anomalyGauge           *ttlGaugeVec
+	anomalyLookback        time.Duration
+	anomalyImpactThreshold float64
+
+	apiRequestsTotal *prometheus.CounterVec
+	apiCostUSDTotal  prometheus.Counter
+	collectorSpacing time.Duration
+
+	baseCfg  aws.Config
+	accounts []Account
+
+	// updateMu serializes updateMetrics runs, since the ticker in
+	// startMetricsUpdater and the on-demand /refresh handler both call it
+	// and both read and write e.accounts.
+	updateMu sync.Mutex
+}
 
-	result, err := e.client.GetCostAndUsage(ctx, costInput)
-	if err != nil {
-		log.Printf("Failed to fetch daily cost data from AWS Cost Explorer: %v", err)
-		return fmt.Errorf("failed to get daily cost and usage: %w", err)
+func NewCostExporter(cfg aws.Config, collectorConfig CollectorConfig) *CostExporter {
+	labelNames := dimensionLabelNames(collectorConfig.Dimensions)
+	metricsTTL := metricsTTLFromEnv()
+
+	collectors := []costCollector{
+		newCostCollector("aws_daily_cost_usd", "Daily AWS cost in USD (usage amount for metric_type UsageQuantity)", types.GranularityDaily, labelNames, metricsTTL, dailyWindow),
+		newCostCollector("aws_monthly_cost_usd", "Monthly AWS cost in USD (usage amount for metric_type UsageQuantity)", types.GranularityMonthly, labelNames, metricsTTL, monthlyWindow),
+		newCostCollector("aws_previous_day_cost_usd", "Previous day AWS cost in USD (stable metric; usage amount for metric_type UsageQuantity)", types.GranularityDaily, labelNames, metricsTTL, previousDayWindow),
+		newCostCollector("aws_previous_month_cost_usd", "Previous month AWS cost in USD (stable metric; usage amount for metric_type UsageQuantity)", types.GranularityMonthly, labelNames, metricsTTL, previousMonthWindow),
+	}
+	for _, c := range collectors {
+		prometheus.MustRegister(c.gauge.gauge)
 	}
 
-	log.Printf("Received %d daily result periods from AWS Cost Explorer", len(result.ResultsByTime))
+	accountCostGauge := newTTLGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aws_account_cost_usd",
+			Help: "AWS cost in USD grouped by linked account and service (organization mode only)",
+		},
+		[]string{"account_id", "account_name", "service"},
+		metricsTTL,
+	)
+	prometheus.MustRegister(accountCostGauge.gauge)
+
+	forecastCostGauge, forecastLowerGauge, forecastUpperGauge := newForecastGauges(metricsTTL)
+	budgetLimitGauge, budgetActualGauge, budgetForecastedGauge, budgetPercentUsedGauge := newBudgetGauges(metricsTTL)
+	savingsPlansGauges, reservationGauges := newCommitmentGauges(metricsTTL)
+	rightsizingGauge, anomalyGauge := newOptimizationGauges(metricsTTL)
+	apiRequestsTotal, apiCostUSDTotal := newAPIMetrics()
+
+	return &CostExporter{
+		client:                      costexplorer.NewFromConfig(cfg),
+		orgClient:                   organizations.NewFromConfig(cfg),
+		stsClient:                   sts.NewFromConfig(cfg),
+		budgetsClient:               budgets.NewFromConfig(cfg),
+		mode:                        modeFromEnv(),
+		assumeRoleName:              assumeRoleNameFromEnv(),
+		accountFilter:               newAccountFilter(os.Getenv("ACCOUNT_ALLOW_LIST"), os.Getenv("ACCOUNT_DENY_LIST")),
+		collectorConfig:             collectorConfig,
+		filter:                      collectorConfig.Filter.expression(),
+		collectors:                  collectors,
+		accountCostGauge:            accountCostGauge,
+		metricsTTL:                  metricsTTL,
+		forecastCostGauge:           forecastCostGauge,
+		forecastCostLowerBoundGauge: forecastLowerGauge,
+		forecastCostUpperBoundGauge: forecastUpperGauge,
+		budgetLimitGauge:            budgetLimitGauge,
+		budgetActualGauge:           budgetActualGauge,
+		budgetForecastedGauge:       budgetForecastedGauge,
+		budgetPercentUsedGauge:      budgetPercentUsedGauge,
+		savingsPlans:                savingsPlansGauges,
+		reservations:                reservationGauges,
+		rightsizingGauge:            rightsizingGauge,
+		anomalyGauge:                anomalyGauge,
+		anomalyLookback:             anomalyLookbackFromEnv(),
+		anomalyImpactThreshold:      anomalyImpactThresholdFromEnv(),
+		apiRequestsTotal:            apiRequestsTotal,
+		apiCostUSDTotal:             apiCostUSDTotal,
+		collectorSpacing:            collectorSpacingFromEnv(),
+		baseCfg:                     cfg,
+	}
+}
 
-	awsCostGauge.Reset()
+// refreshAccounts (re)resolves the set of accounts to collect cost data
+// for. In single-account mode this is just the caller's own account; in
+// organization mode it is every allowed, reachable member account.
+func (e *CostExporter) refreshAccounts(ctx context.Context) error {
+	if e.mode != ModeOrganization {
+		e.accounts = []Account{currentAccount(ctx, e.stsClient)}
+		return nil
+	}
 
-	metricsCount := 0
-	for _, resultByTime := range result.ResultsByTime {
-		for _, group := range resultByTime.Groups {
-			if len(group.Keys) >= 2 {
-				service := group.Keys[0]
-				region := group.Keys[1]
-				if cost, ok := group.Metrics["UnblendedCost"]; ok && cost.Amount != nil {
-					amount, err := strconv.ParseFloat(*cost.Amount, 64)
-					if err == nil {
-						awsCostGauge.WithLabelValues(service, region).Set(amount)
-						metricsCount++
-					}
-				}
-			}
-		}
+	accounts, err := discoverAccounts(ctx, e.baseCfg, e.orgClient, e.stsClient, e.assumeRoleName, e.accountFilter)
+	if err != nil {
+		return fmt.Errorf("failed to discover organization accounts: %w", err)
 	}
 
-	log.Printf("Updated %d daily cost metrics for period %s to %s", metricsCount, start, end)
+	log.Printf("Discovered %d organization accounts to collect cost data for", len(accounts))
+	e.accounts = accounts
 	return nil
 }
 
-func (e *CostExporter) updateMonthlyMetrics(ctx context.Context) error {
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	
-	start := startOfMonth.Format("2006-01-02")
-	end := now.Format("2006-01-02")
+// clientFor returns the Cost Explorer client to use for account, falling
+// back to the exporter's default client in single-account mode.
+func (e *CostExporter) clientFor(account Account) *costexplorer.Client {
+	if account.client != nil {
+		return account.client
+	}
+	return e.client
+}
 
-	log.Printf("Fetching monthly cost data from %s to %s", start, end)
+// runCollector is the single parameterized collector all of the
+// exporter's time-windowed cost metrics run through: it queries
+// GetCostAndUsage for each account over the collector's time window,
+// grouped by the configured dimensions and restricted by the configured
+// filter expression, and sets the result on the collector's gauge.
+func (e *CostExporter) runCollector(ctx context.Context, c costCollector) error {
+	start, end := c.timeWindow(time.Now())
 
-	costInput := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &types.DateInterval{
-			Start: &start,
-			End:   &end,
-		},
-		Granularity: types.GranularityMonthly,
-		Metrics:     []string{"UnblendedCost"},
-		GroupBy: []types.GroupDefinition{
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionService)}[0],
-			},
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionRegion)}[0],
-			},
-		},
-	}
+	log.Printf("Fetching %s data from %s to %s", c.name, start, end)
 
-	result, err := e.client.GetCostAndUsage(ctx, costInput)
-	if err != nil {
-		log.Printf("Failed to fetch monthly cost data from AWS Cost Explorer: %v", err)
-		return fmt.Errorf("failed to get monthly cost and usage: %w", err)
-	}
+	dimensions := e.collectorConfig.Dimensions
+	metricsCount := 0
+	for _, account := range e.accounts {
+		costInput := &costexplorer.GetCostAndUsageInput{
+			TimePeriod: &types.DateInterval{
+				Start: &start,
+				End:   &end,
+			},
+			Granularity: c.granularity,
+			Metrics:     costMetricTypes,
+			GroupBy:     groupDefinitions(dimensions),
+			Filter:      e.filter,
+		}
 
-	log.Printf("Received %d monthly result periods from AWS Cost Explorer", len(result.ResultsByTime))
+		client := e.clientFor(account)
+		result, err := callCostExplorer(ctx, e, "GetCostAndUsage", func() (*costexplorer.GetCostAndUsageOutput, error) {
+			return client.GetCostAndUsage(ctx, costInput)
+		})
+		if err != nil {
+			log.Printf("Failed to fetch %s for account %s from AWS Cost Explorer: %v", c.name, account.ID, err)
+			return fmt.Errorf("failed to get %s for account %s: %w", c.name, account.ID, err)
+		}
 
-	awsMonthlyCostGauge.Reset()
+		log.Printf("Received %d %s result periods for account %s from AWS Cost Explorer", len(result.ResultsByTime), c.name, account.ID)
 
-	metricsCount := 0
-	for _, resultByTime := range result.ResultsByTime {
-		for _, group := range resultByTime.Groups {
-			if len(group.Keys) >= 2 {
-				service := group.Keys[0]
-				region := group.Keys[1]
-				if cost, ok := group.Metrics["UnblendedCost"]; ok && cost.Amount != nil {
+		for _, resultByTime := range result.ResultsByTime {
+			for _, group := range resultByTime.Groups {
+				if len(group.Keys) != len(dimensions) {
+					continue
+				}
+				for _, metricType := range costMetricTypes {
+					cost, ok := group.Metrics[metricType]
+					if !ok || cost.Amount == nil {
+						continue
+					}
 					amount, err := strconv.ParseFloat(*cost.Amount, 64)
-					if err == nil {
-						awsMonthlyCostGauge.WithLabelValues(service, region).Set(amount)
-						metricsCount++
+					if err != nil {
+						continue
 					}
+					labelValues := append(append([]string{}, group.Keys...), metricType, account.ID, account.Name)
+					c.gauge.Set(labelValues, amount)
+					metricsCount++
 				}
 			}
 		}
 	}
 
-	log.Printf("Updated %d monthly cost metrics for period %s to %s", metricsCount, start, end)
+	log.Printf("Updated %d %s metrics for period %s to %s", metricsCount, c.name, start, end)
 	return nil
 }
 
-func (e *CostExporter) updatePreviousDayMetrics(ctx context.Context) error {
+// updateAccountCostMetrics populates aws_account_cost_usd with a single,
+// consolidated GetCostAndUsage call grouped by linked account and service.
+// It only runs in organization mode, using the management account's view
+// of consolidated billing rather than assuming a role into each account.
+func (e *CostExporter) updateAccountCostMetrics(ctx context.Context) error {
+	if e.mode != ModeOrganization {
+		return nil
+	}
+
 	now := time.Now()
-	twoDaysAgo := now.AddDate(0, 0, -2)
 	yesterday := now.AddDate(0, 0, -1)
-	
-	start := twoDaysAgo.Format("2006-01-02")
-	end := yesterday.Format("2006-01-02")
 
-	log.Printf("Fetching previous day cost data from %s to %s", start, end)
+	start := yesterday.Format("2006-01-02")
+	end := now.Format("2006-01-02")
+
+	log.Printf("Fetching per-account cost data from %s to %s", start, end)
 
 	costInput := &costexplorer.GetCostAndUsageInput{
 		TimePeriod: &types.DateInterval{
@@ -203,35 +311,40 @@ func (e *CostExporter) updatePreviousDayMetrics(ctx context.Context) error {
 		GroupBy: []types.GroupDefinition{
 			{
 				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionService)}[0],
+				Key:  &[]string{string(types.DimensionLinkedAccount)}[0],
 			},
 			{
 				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionRegion)}[0],
+				Key:  &[]string{string(types.DimensionService)}[0],
 			},
 		},
 	}
 
-	result, err := e.client.GetCostAndUsage(ctx, costInput)
+	result, err := callCostExplorer(ctx, e, "GetCostAndUsage", func() (*costexplorer.GetCostAndUsageOutput, error) {
+		return e.client.GetCostAndUsage(ctx, costInput)
+	})
 	if err != nil {
-		log.Printf("Failed to fetch previous day cost data from AWS Cost Explorer: %v", err)
-		return fmt.Errorf("failed to get previous day cost and usage: %w", err)
+		log.Printf("Failed to fetch per-account cost data from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get per-account cost and usage: %w", err)
 	}
 
-	log.Printf("Received %d previous day result periods from AWS Cost Explorer", len(result.ResultsByTime))
+	log.Printf("Received %d per-account result periods from AWS Cost Explorer", len(result.ResultsByTime))
 
-	awsPreviousDayCostGauge.Reset()
+	accountNames := make(map[string]string, len(e.accounts))
+	for _, account := range e.accounts {
+		accountNames[account.ID] = account.Name
+	}
 
 	metricsCount := 0
 	for _, resultByTime := range result.ResultsByTime {
 		for _, group := range resultByTime.Groups {
 			if len(group.Keys) >= 2 {
-				service := group.Keys[0]
-				region := group.Keys[1]
+				accountID := group.Keys[0]
+				service := group.Keys[1]
 				if cost, ok := group.Metrics["UnblendedCost"]; ok && cost.Amount != nil {
 					amount, err := strconv.ParseFloat(*cost.Amount, 64)
 					if err == nil {
-						awsPreviousDayCostGauge.WithLabelValues(service, region).Set(amount)
+						e.accountCostGauge.Set([]string{accountID, accountNames[accountID], service}, amount)
 						metricsCount++
 					}
 				}
@@ -239,88 +352,63 @@ func (e *CostExporter) updatePreviousDayMetrics(ctx context.Context) error {
 		}
 	}
 
-	log.Printf("Updated %d previous day cost metrics for period %s to %s", metricsCount, start, end)
+	log.Printf("Updated %d per-account cost metrics for period %s to %s", metricsCount, start, end)
 	return nil
 }
 
-func (e *CostExporter) updatePreviousMonthMetrics(ctx context.Context) error {
-	now := time.Now()
-	// Get the first day of the previous month
-	firstDayOfCurrentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	firstDayOfPreviousMonth := firstDayOfCurrentMonth.AddDate(0, -1, 0)
-	
-	start := firstDayOfPreviousMonth.Format("2006-01-02")
-	end := firstDayOfCurrentMonth.Format("2006-01-02")
-
-	log.Printf("Fetching previous month cost data from %s to %s", start, end)
+func (e *CostExporter) updateMetrics(ctx context.Context) error {
+	e.updateMu.Lock()
+	defer e.updateMu.Unlock()
 
-	costInput := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &types.DateInterval{
-			Start: &start,
-			End:   &end,
-		},
-		Granularity: types.GranularityMonthly,
-		Metrics:     []string{"UnblendedCost"},
-		GroupBy: []types.GroupDefinition{
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionService)}[0],
-			},
-			{
-				Type: types.GroupDefinitionTypeDimension,
-				Key:  &[]string{string(types.DimensionRegion)}[0],
-			},
-		},
+	if err := e.refreshAccounts(ctx); err != nil {
+		return err
 	}
 
-	result, err := e.client.GetCostAndUsage(ctx, costInput)
-	if err != nil {
-		log.Printf("Failed to fetch previous month cost data from AWS Cost Explorer: %v", err)
-		return fmt.Errorf("failed to get previous month cost and usage: %w", err)
+	for i, c := range e.collectors {
+		if i > 0 {
+			e.spaceCostExplorerCall()
+		}
+		if err := e.runCollector(ctx, c); err != nil {
+			return err
+		}
 	}
 
-	log.Printf("Received %d previous month result periods from AWS Cost Explorer", len(result.ResultsByTime))
-
-	awsPreviousMonthCostGauge.Reset()
+	e.spaceCostExplorerCall()
+	if err := e.updateAccountCostMetrics(ctx); err != nil {
+		return err
+	}
 
-	metricsCount := 0
-	for _, resultByTime := range result.ResultsByTime {
-		for _, group := range resultByTime.Groups {
-			if len(group.Keys) >= 2 {
-				service := group.Keys[0]
-				region := group.Keys[1]
-				if cost, ok := group.Metrics["UnblendedCost"]; ok && cost.Amount != nil {
-					amount, err := strconv.ParseFloat(*cost.Amount, 64)
-					if err == nil {
-						awsPreviousMonthCostGauge.WithLabelValues(service, region).Set(amount)
-						metricsCount++
-					}
-				}
-			}
-		}
+	e.spaceCostExplorerCall()
+	if err := e.updateForecastMetrics(ctx); err != nil {
+		return err
 	}
 
-	log.Printf("Updated %d previous month cost metrics for period %s to %s", metricsCount, start, end)
-	return nil
-}
+	// Budgets is a separate, non-Cost-Explorer API and isn't part of the
+	// billed Cost Explorer burst collectorSpacing exists to spread out.
+	if err := e.updateBudgetMetrics(ctx); err != nil {
+		return err
+	}
 
-func (e *CostExporter) updateMetrics(ctx context.Context) error {
-	if err := e.updateDailyMetrics(ctx); err != nil {
+	e.spaceCostExplorerCall()
+	if err := e.updateSavingsPlansMetrics(ctx); err != nil {
 		return err
 	}
-	
-	if err := e.updateMonthlyMetrics(ctx); err != nil {
+
+	e.spaceCostExplorerCall()
+	if err := e.updateReservationMetrics(ctx); err != nil {
 		return err
 	}
-	
-	if err := e.updatePreviousDayMetrics(ctx); err != nil {
+
+	e.spaceCostExplorerCall()
+	if err := e.updateRightsizingMetrics(ctx); err != nil {
 		return err
 	}
-	
-	if err := e.updatePreviousMonthMetrics(ctx); err != nil {
+
+	e.spaceCostExplorerCall()
+	if err := e.updateAnomalyMetrics(ctx); err != nil {
 		return err
 	}
-	
+
 	return nil
 }
 
@@ -357,8 +445,13 @@ func main() {
 		log.Fatalf("Failed to load AWS config: %v", err)
 	}
 
-	client := costexplorer.NewFromConfig(cfg)
-	exporter := NewCostExporter(client)
+	collectorConfig, err := loadCollectorConfig(collectorConfigPathFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to load collector config: %v", err)
+	}
+
+	exporter := NewCostExporter(cfg, collectorConfig)
+	log.Printf("Running in %s mode, grouping by %v", exporter.mode, collectorConfig.Dimensions)
 
 	// Update metrics immediately on startup
 	log.Printf("Updating metrics on startup...")
@@ -367,12 +460,14 @@ func main() {
 	}
 
 	go exporter.startMetricsUpdater(ctx)
+	go exporter.startTTLSweeper(ctx)
 
 	http.Handle("/metrics", promhttp.Handler())
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	http.HandleFunc("/refresh", refreshHandler(exporter, refreshTokenFromEnv()))
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultAnomalyLookback                   = 7 * 24 * time.Hour
+	defaultAnomalyImpactThresholdUSD float64 = 0
+)
+
+// newOptimizationGauges builds the TTL-backed gauges behind the
+// rightsizing and cost anomaly metrics and registers them with
+// Prometheus. Unlike the rest of the exporter, these are meant to be
+// acted on directly rather than just watched: a rightsizing
+// recommendation is a concrete instance to resize, and an anomaly is a
+// concrete spend spike to investigate.
+func newOptimizationGauges(ttl time.Duration) (rightsizing, anomaly *ttlGaugeVec) {
+	rightsizing = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_rightsizing_estimated_monthly_savings_usd",
+		Help: "Estimated monthly savings in USD from a Cost Explorer rightsizing recommendation",
+	}, []string{"account_id", "resource_id", "current_type", "recommended_type", "action"}, ttl)
+
+	anomaly = newTTLGaugeVec(prometheus.GaugeOpts{
+		Name: "aws_cost_anomaly_impact_usd",
+		Help: "Total dollar impact of a detected AWS Cost Anomaly",
+	}, []string{"anomaly_id", "service", "root_cause", "start_timestamp"}, ttl)
+
+	prometheus.MustRegister(rightsizing.gauge, anomaly.gauge)
+	return rightsizing, anomaly
+}
+
+// anomalyLookbackFromEnv returns how far back to look for cost anomalies,
+// configurable since the default subscription lookback isn't always what
+// an operator wants to alert on.
+func anomalyLookbackFromEnv() time.Duration {
+	raw := os.Getenv("ANOMALY_LOOKBACK")
+	if raw == "" {
+		return defaultAnomalyLookback
+	}
+
+	lookback, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid ANOMALY_LOOKBACK %q, falling back to %s: %v", raw, defaultAnomalyLookback, err)
+		return defaultAnomalyLookback
+	}
+	return lookback
+}
+
+// anomalyImpactThresholdFromEnv returns the minimum total dollar impact
+// an anomaly must have to be exposed as a metric, filtering out noise
+// from anomalies too small to be worth an operator's attention.
+func anomalyImpactThresholdFromEnv() float64 {
+	raw := os.Getenv("ANOMALY_IMPACT_THRESHOLD_USD")
+	if raw == "" {
+		return defaultAnomalyImpactThresholdUSD
+	}
+
+	threshold, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("Invalid ANOMALY_IMPACT_THRESHOLD_USD %q, falling back to %.2f: %v", raw, defaultAnomalyImpactThresholdUSD, err)
+		return defaultAnomalyImpactThresholdUSD
+	}
+	return threshold
+}
+
+// updateRightsizingMetrics fetches EC2 rightsizing recommendations and
+// exposes each one's estimated monthly savings as a gauge.
+func (e *CostExporter) updateRightsizingMetrics(ctx context.Context) error {
+	log.Printf("Fetching EC2 rightsizing recommendations")
+
+	accountID := currentAccount(ctx, e.stsClient).ID
+
+	metricsCount := 0
+	var nextPageToken *string
+	for {
+		result, err := callCostExplorer(ctx, e, "GetRightsizingRecommendation", func() (*costexplorer.GetRightsizingRecommendationOutput, error) {
+			return e.client.GetRightsizingRecommendation(ctx, &costexplorer.GetRightsizingRecommendationInput{
+				Service:       aws.String("AmazonEC2"),
+				NextPageToken: nextPageToken,
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to fetch rightsizing recommendations from AWS Cost Explorer: %v", err)
+			return fmt.Errorf("failed to get rightsizing recommendations: %w", err)
+		}
+
+		for _, rec := range result.RightsizingRecommendations {
+			resourceID, currentType, savings := rightsizingDetails(rec)
+			if resourceID == "" {
+				continue
+			}
+
+			recAccountID := aws.ToString(rec.AccountId)
+			if recAccountID == "" {
+				recAccountID = accountID
+			}
+
+			e.rightsizingGauge.Set(
+				[]string{recAccountID, resourceID, currentType, recommendedInstanceType(rec), string(rec.RightsizingType)},
+				savings,
+			)
+			metricsCount++
+		}
+
+		if result.NextPageToken == nil || *result.NextPageToken == "" {
+			break
+		}
+		nextPageToken = result.NextPageToken
+	}
+
+	log.Printf("Updated %d rightsizing recommendation metrics", metricsCount)
+	return nil
+}
+
+func rightsizingDetails(rec types.RightsizingRecommendation) (resourceID, currentType string, estimatedMonthlySavings float64) {
+	if rec.CurrentInstance != nil {
+		resourceID = aws.ToString(rec.CurrentInstance.ResourceId)
+		if rec.CurrentInstance.ResourceDetails != nil && rec.CurrentInstance.ResourceDetails.EC2ResourceDetails != nil {
+			currentType = aws.ToString(rec.CurrentInstance.ResourceDetails.EC2ResourceDetails.InstanceType)
+		}
+	}
+
+	if rec.ModifyRecommendationDetail != nil {
+		// Use the same target instance recommendedInstanceType reports.
+		for _, target := range rec.ModifyRecommendationDetail.TargetInstances {
+			if target.ResourceDetails == nil || target.ResourceDetails.EC2ResourceDetails == nil {
+				continue
+			}
+			if target.EstimatedMonthlySavings != nil {
+				if savings, err := strconv.ParseFloat(*target.EstimatedMonthlySavings, 64); err == nil {
+					estimatedMonthlySavings = savings
+				}
+			}
+			break
+		}
+	}
+
+	if rec.TerminateRecommendationDetail != nil && rec.TerminateRecommendationDetail.EstimatedMonthlySavings != nil {
+		if savings, err := strconv.ParseFloat(*rec.TerminateRecommendationDetail.EstimatedMonthlySavings, 64); err == nil {
+			estimatedMonthlySavings = savings
+		}
+	}
+
+	return resourceID, currentType, estimatedMonthlySavings
+}
+
+func recommendedInstanceType(rec types.RightsizingRecommendation) string {
+	if rec.ModifyRecommendationDetail == nil {
+		return ""
+	}
+	for _, target := range rec.ModifyRecommendationDetail.TargetInstances {
+		if target.ResourceDetails != nil && target.ResourceDetails.EC2ResourceDetails != nil {
+			return aws.ToString(target.ResourceDetails.EC2ResourceDetails.InstanceType)
+		}
+	}
+	return ""
+}
+
+// updateAnomalyMetrics fetches Cost Anomaly Detection findings over the
+// configured lookback window and exposes each anomaly's total dollar
+// impact as a gauge, dropping anomalies below the configured threshold.
+func (e *CostExporter) updateAnomalyMetrics(ctx context.Context) error {
+	now := time.Now()
+	start := now.Add(-e.anomalyLookback)
+
+	startStr := start.Format("2006-01-02T15:04:05Z")
+	endStr := now.Format("2006-01-02T15:04:05Z")
+
+	log.Printf("Fetching cost anomalies from %s to %s", startStr, endStr)
+
+	result, err := callCostExplorer(ctx, e, "GetAnomalies", func() (*costexplorer.GetAnomaliesOutput, error) {
+		return e.client.GetAnomalies(ctx, &costexplorer.GetAnomaliesInput{
+			DateInterval: &types.AnomalyDateInterval{
+				StartDate: &startStr,
+				EndDate:   &endStr,
+			},
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to fetch cost anomalies from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get anomalies: %w", err)
+	}
+
+	metricsCount := 0
+	for _, anomaly := range result.Anomalies {
+		if anomaly.Impact == nil || anomaly.Impact.TotalImpact < e.anomalyImpactThreshold {
+			continue
+		}
+
+		var rootCauseService string
+		if len(anomaly.RootCauses) > 0 {
+			rootCauseService = aws.ToString(anomaly.RootCauses[0].Service)
+		}
+
+		e.anomalyGauge.Set([]string{
+			aws.ToString(anomaly.AnomalyId),
+			aws.ToString(anomaly.DimensionValue),
+			rootCauseService,
+			aws.ToString(anomaly.AnomalyStartDate),
+		}, anomaly.Impact.TotalImpact)
+		metricsCount++
+	}
+
+	log.Printf("Updated %d cost anomaly metrics for lookback window %s to %s", metricsCount, startStr, endStr)
+	return nil
+}
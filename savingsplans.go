@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newCommitmentGauges builds the TTL-backed gauges behind the Savings
+// Plans and Reserved Instance utilization/coverage metrics and registers
+// them with Prometheus. These surface the commitment-based discounting
+// that the unblended-cost-only view of the exporter hides, which is what
+// FinOps teams actually watch to judge whether a commitment is paying
+// for itself.
+func newCommitmentGauges(ttl time.Duration) (sp, ri *commitmentGauges) {
+	sp = &commitmentGauges{
+		utilization: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_savings_plans_utilization_percent",
+			Help: "Percentage of Savings Plans commitment used",
+		}, nil, ttl),
+		coverage: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_savings_plans_coverage_percent",
+			Help: "Percentage of eligible spend covered by Savings Plans",
+		}, nil, ttl),
+		unusedCommitment: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_savings_plans_unused_commitment_usd",
+			Help: "Unused Savings Plans commitment in USD",
+		}, nil, ttl),
+	}
+
+	ri = &commitmentGauges{
+		utilization: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_reservation_utilization_percent",
+			Help: "Percentage of Reserved Instance commitment used",
+		}, nil, ttl),
+		coverage: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_reservation_coverage_percent",
+			Help: "Percentage of eligible usage covered by Reserved Instances",
+		}, nil, ttl),
+		unusedCommitment: newTTLGaugeVec(prometheus.GaugeOpts{
+			Name: "aws_reservation_unused_commitment_usd",
+			Help: "Cost of unused Reserved Instance hours in USD",
+		}, nil, ttl),
+	}
+
+	prometheus.MustRegister(
+		sp.utilization.gauge, sp.coverage.gauge, sp.unusedCommitment.gauge,
+		ri.utilization.gauge, ri.coverage.gauge, ri.unusedCommitment.gauge,
+	)
+
+	return sp, ri
+}
+
+// commitmentGauges groups the three gauges emitted for a commitment
+// mechanism (Savings Plans or Reserved Instances): how much of it is
+// being used, how much of eligible spend it covers, and how much of it
+// is going to waste.
+type commitmentGauges struct {
+	utilization      *ttlGaugeVec
+	coverage         *ttlGaugeVec
+	unusedCommitment *ttlGaugeVec
+}
+
+// commitmentLookbackWindow returns yesterday's date range, the window
+// AWS Cost Explorer's utilization/coverage APIs report most reliably
+// (today's numbers are typically incomplete).
+func commitmentLookbackWindow(now time.Time) (start, end string) {
+	yesterday := now.AddDate(0, 0, -1)
+	return yesterday.Format("2006-01-02"), now.Format("2006-01-02")
+}
+
+// updateSavingsPlansMetrics fetches Savings Plans utilization and
+// coverage for the previous day and exposes them as gauges.
+func (e *CostExporter) updateSavingsPlansMetrics(ctx context.Context) error {
+	start, end := commitmentLookbackWindow(time.Now())
+	timePeriod := &types.DateInterval{Start: &start, End: &end}
+
+	log.Printf("Fetching Savings Plans utilization and coverage from %s to %s", start, end)
+
+	utilization, err := callCostExplorer(ctx, e, "GetSavingsPlansUtilization", func() (*costexplorer.GetSavingsPlansUtilizationOutput, error) {
+		return e.client.GetSavingsPlansUtilization(ctx, &costexplorer.GetSavingsPlansUtilizationInput{
+			TimePeriod: timePeriod,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to fetch Savings Plans utilization from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get savings plans utilization: %w", err)
+	}
+
+	if utilization.Total != nil && utilization.Total.Utilization != nil {
+		setFromString(e.savingsPlans.utilization, nil, utilization.Total.Utilization.UtilizationPercentage)
+		setFromString(e.savingsPlans.unusedCommitment, nil, utilization.Total.Utilization.UnusedCommitment)
+	}
+
+	e.spaceCostExplorerCall()
+	coverage, err := callCostExplorer(ctx, e, "GetSavingsPlansCoverage", func() (*costexplorer.GetSavingsPlansCoverageOutput, error) {
+		return e.client.GetSavingsPlansCoverage(ctx, &costexplorer.GetSavingsPlansCoverageInput{
+			TimePeriod: timePeriod,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to fetch Savings Plans coverage from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get savings plans coverage: %w", err)
+	}
+
+	for _, c := range coverage.SavingsPlansCoverages {
+		if c.Coverage != nil {
+			setFromString(e.savingsPlans.coverage, nil, c.Coverage.CoveragePercentage)
+		}
+	}
+
+	log.Printf("Updated Savings Plans utilization and coverage metrics for period %s to %s", start, end)
+	return nil
+}
+
+// updateReservationMetrics fetches Reserved Instance utilization and
+// coverage for the previous day and exposes them as gauges.
+func (e *CostExporter) updateReservationMetrics(ctx context.Context) error {
+	start, end := commitmentLookbackWindow(time.Now())
+	timePeriod := &types.DateInterval{Start: &start, End: &end}
+
+	log.Printf("Fetching Reserved Instance utilization and coverage from %s to %s", start, end)
+
+	utilization, err := callCostExplorer(ctx, e, "GetReservationUtilization", func() (*costexplorer.GetReservationUtilizationOutput, error) {
+		return e.client.GetReservationUtilization(ctx, &costexplorer.GetReservationUtilizationInput{
+			TimePeriod: timePeriod,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to fetch Reserved Instance utilization from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get reservation utilization: %w", err)
+	}
+
+	if utilization.Total != nil {
+		setFromString(e.reservations.utilization, nil, utilization.Total.UtilizationPercentage)
+		setFromString(e.reservations.unusedCommitment, nil, utilization.Total.RICostForUnusedHours)
+	}
+
+	e.spaceCostExplorerCall()
+	coverage, err := callCostExplorer(ctx, e, "GetReservationCoverage", func() (*costexplorer.GetReservationCoverageOutput, error) {
+		return e.client.GetReservationCoverage(ctx, &costexplorer.GetReservationCoverageInput{
+			TimePeriod: timePeriod,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to fetch Reserved Instance coverage from AWS Cost Explorer: %v", err)
+		return fmt.Errorf("failed to get reservation coverage: %w", err)
+	}
+
+	if coverage.Total != nil && coverage.Total.CoverageHours != nil {
+		setFromString(e.reservations.coverage, nil, coverage.Total.CoverageHours.CoverageHoursPercentage)
+	}
+
+	log.Printf("Updated Reserved Instance utilization and coverage metrics for period %s to %s", start, end)
+	return nil
+}
+
+func setFromString(gauge *ttlGaugeVec, labelValues []string, raw *string) {
+	if raw == nil {
+		return
+	}
+	value, err := strconv.ParseFloat(*raw, 64)
+	if err != nil {
+		return
+	}
+	gauge.Set(labelValues, value)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// costExplorerRequestCostUSD is what AWS bills per Cost Explorer API
+// request, regardless of operation or how much data it returns.
+const costExplorerRequestCostUSD = 0.01
+
+const (
+	defaultAPIBackoffBase   = 500 * time.Millisecond
+	defaultAPIBackoffMax    = 30 * time.Second
+	defaultAPIMaxRetries    = 5
+	defaultCollectorSpacing = 2 * time.Second
+)
+
+// newAPIMetrics builds the self-observability counters behind the
+// exporter's own Cost Explorer API usage and registers them with
+// Prometheus, so operators can see and alert on their own Cost Explorer
+// spend rather than discovering it on their AWS bill.
+func newAPIMetrics() (requestsTotal *prometheus.CounterVec, costUSDTotal prometheus.Counter) {
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aws_cost_exporter_api_requests_total",
+		Help: "Total number of AWS Cost Explorer API requests made by the exporter, by operation and outcome",
+	}, []string{"operation", "status"})
+
+	costUSDTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "aws_cost_exporter_api_cost_usd_total",
+		Help: "Cumulative estimated USD cost of AWS Cost Explorer API requests made by the exporter, at $0.01 per request",
+	})
+
+	prometheus.MustRegister(requestsTotal, costUSDTotal)
+	return requestsTotal, costUSDTotal
+}
+
+// collectorSpacingFromEnv returns the delay to insert between the
+// exporter's time-windowed GetCostAndUsage collectors, spreading out what
+// used to be a tight burst of billed requests against one ticker.
+func collectorSpacingFromEnv() time.Duration {
+	raw := os.Getenv("COLLECTOR_SPACING")
+	if raw == "" {
+		return defaultCollectorSpacing
+	}
+
+	spacing, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid COLLECTOR_SPACING %q, falling back to %s: %v", raw, defaultCollectorSpacing, err)
+		return defaultCollectorSpacing
+	}
+	return spacing
+}
+
+// spaceCostExplorerCall sleeps for the configured collector spacing
+// before a subsequent Cost Explorer request, spreading out what would
+// otherwise be a tight, back-to-back burst of billed requests every time
+// the scheduler ticks or /refresh is hit.
+func (e *CostExporter) spaceCostExplorerCall() {
+	if e.collectorSpacing > 0 {
+		time.Sleep(e.collectorSpacing)
+	}
+}
+
+// callCostExplorer invokes a Cost Explorer API call, retrying with
+// exponential backoff when AWS returns ThrottlingException or
+// LimitExceededException, and recording every attempt - successful,
+// throttled, or otherwise failed - against the exporter's
+// aws_cost_exporter_api_requests_total and aws_cost_exporter_api_cost_usd_total
+// metrics. e is passed explicitly because Go methods cannot be generic.
+func callCostExplorer[T any](ctx context.Context, e *CostExporter, operation string, call func() (T, error)) (T, error) {
+	backoff := defaultAPIBackoffBase
+
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		e.apiCostUSDTotal.Add(costExplorerRequestCostUSD)
+
+		if err == nil {
+			e.apiRequestsTotal.WithLabelValues(operation, "success").Inc()
+			return result, nil
+		}
+
+		if !isThrottlingError(err) || attempt >= defaultAPIMaxRetries {
+			e.apiRequestsTotal.WithLabelValues(operation, "error").Inc()
+			return result, err
+		}
+
+		e.apiRequestsTotal.WithLabelValues(operation, "throttled").Inc()
+
+		sleep := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		log.Printf("Cost Explorer %s throttled (attempt %d/%d), backing off for %s", operation, attempt+1, defaultAPIMaxRetries+1, sleep)
+
+		select {
+		case <-time.After(sleep):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > defaultAPIBackoffMax {
+			backoff = defaultAPIBackoffMax
+		}
+	}
+}
+
+// isThrottlingError reports whether err is an AWS-side throttling
+// response that's worth backing off and retrying, rather than a
+// permanent failure (bad request, missing permissions, etc.).
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "LimitExceededException":
+		return true
+	default:
+		return false
+	}
+}
+
+// refreshTokenFromEnv returns the bearer token required to call /refresh.
+// An empty token disables the endpoint entirely rather than leaving it
+// open, since an unconfigured deployment should fail closed.
+func refreshTokenFromEnv() string {
+	return os.Getenv("REFRESH_TOKEN")
+}
+
+// refreshHandler triggers an immediate, synchronous metrics update
+// instead of waiting for the next scheduler tick, for operators who need
+// fresh numbers right now. It requires a bearer token matching
+// REFRESH_TOKEN; if that env var is unset the endpoint always rejects.
+func refreshHandler(e *CostExporter, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" || r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		log.Printf("On-demand refresh requested via /refresh")
+		if err := e.updateMetrics(r.Context()); err != nil {
+			log.Printf("On-demand refresh failed: %v", err)
+			http.Error(w, fmt.Sprintf("refresh failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("refreshed"))
+	}
+}
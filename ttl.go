@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultMetricsTTL is used when METRICS_TTL is unset.
+const defaultMetricsTTL = time.Hour
+
+// ttlGaugeVec wraps a prometheus.GaugeVec with per-label-set freshness
+// tracking, modeled on the statsd_exporter's time series expiration. It
+// replaces the blanket GaugeVec.Reset() the exporter used to call on every
+// refresh, which deleted every series up front and left a gap before the
+// next scrape repopulated them - causing rate()/increase() queries and
+// alerts on these metrics to flap. Instead, each label combination is
+// stamped with the time it was last set, and sweep() evicts only the
+// combinations that have not been refreshed within the TTL.
+type ttlGaugeVec struct {
+	gauge *prometheus.GaugeVec
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	updated map[string]seriesEntry
+}
+
+type seriesEntry struct {
+	labelValues []string
+	at          time.Time
+}
+
+func newTTLGaugeVec(opts prometheus.GaugeOpts, labelNames []string, ttl time.Duration) *ttlGaugeVec {
+	return &ttlGaugeVec{
+		gauge:   prometheus.NewGaugeVec(opts, labelNames),
+		ttl:     ttl,
+		updated: make(map[string]seriesEntry),
+	}
+}
+
+// Set sets the gauge for labelValues and stamps it as freshly updated.
+func (t *ttlGaugeVec) Set(labelValues []string, value float64) {
+	t.gauge.WithLabelValues(labelValues...).Set(value)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.updated[seriesKey(labelValues)] = seriesEntry{labelValues: labelValues, at: time.Now()}
+}
+
+// sweep deletes every series that has not been refreshed within the TTL.
+// A ttl of 0 (or less) disables eviction entirely.
+func (t *ttlGaugeVec) sweep() int {
+	if t.ttl <= 0 {
+		return 0
+	}
+
+	cutoff := time.Now().Add(-t.ttl)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	evicted := 0
+	for key, entry := range t.updated {
+		if entry.at.Before(cutoff) {
+			t.gauge.DeleteLabelValues(entry.labelValues...)
+			delete(t.updated, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\x1f")
+}
+
+func metricsTTLFromEnv() time.Duration {
+	raw := os.Getenv("METRICS_TTL")
+	if raw == "" {
+		return defaultMetricsTTL
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid METRICS_TTL %q, falling back to %s: %v", raw, defaultMetricsTTL, err)
+		return defaultMetricsTTL
+	}
+	return ttl
+}
+
+// startTTLSweeper periodically evicts stale series from every gauge the
+// exporter owns. It runs at a quarter of the TTL (but no more often than
+// once a minute) so that a series has several chances to be swept soon
+// after it goes stale without sweeping on every tick. A TTL of 0 disables
+// the sweeper, leaving series in place forever (the pre-TTL behavior,
+// minus the Reset() gap).
+func (e *CostExporter) startTTLSweeper(ctx context.Context) {
+	if e.metricsTTL <= 0 {
+		log.Printf("Metrics TTL eviction disabled (metrics_ttl=0)")
+		return
+	}
+
+	interval := e.metricsTTL / 4
+	if interval < time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.sweepStaleSeries()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *CostExporter) sweepStaleSeries() {
+	gauges := []*ttlGaugeVec{
+		e.accountCostGauge,
+		e.forecastCostGauge,
+		e.forecastCostLowerBoundGauge,
+		e.forecastCostUpperBoundGauge,
+		e.budgetLimitGauge,
+		e.budgetActualGauge,
+		e.budgetForecastedGauge,
+		e.budgetPercentUsedGauge,
+		e.savingsPlans.utilization,
+		e.savingsPlans.coverage,
+		e.savingsPlans.unusedCommitment,
+		e.reservations.utilization,
+		e.reservations.coverage,
+		e.reservations.unusedCommitment,
+		e.rightsizingGauge,
+		e.anomalyGauge,
+	}
+
+	evicted := 0
+	for _, c := range e.collectors {
+		evicted += c.gauge.sweep()
+	}
+	for _, g := range gauges {
+		evicted += g.sweep()
+	}
+
+	if evicted > 0 {
+		log.Printf("Evicted %d stale cost metric series older than %s", evicted, e.metricsTTL)
+	}
+}